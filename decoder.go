@@ -0,0 +1,105 @@
+package openvpn
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// RecordType identifies which kind of record a Record holds.
+type RecordType int
+
+const (
+	RecordUpdated RecordType = iota
+	RecordClient
+	RecordRoute
+	RecordStats
+)
+
+// Record is a single parsed entry from a status log, as produced by a
+// Decoder. Only the field matching Type is populated.
+type Record struct {
+	Type    RecordType
+	Updated time.Time
+	Client  Client
+	Route   Route
+	Stats   Stats
+}
+
+// Decoder offers pull-style iteration over a status log via Next, for
+// callers (e.g. an exporter) who'd rather drive the loop themselves than
+// hand ParseStream a Handler.
+type Decoder struct {
+	records chan Record
+	errc    chan error
+	done    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// NewDecoder starts decoding r in the background; call Next to read the
+// records it finds.
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{
+		records: make(chan Record),
+		errc:    make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(d.records)
+		d.errc <- ParseStream(r, decoderHandler{d})
+	}()
+	return d
+}
+
+// Next returns the next record in the log. It returns io.EOF once the log
+// has been fully read.
+func (d *Decoder) Next() (Record, error) {
+	rec, ok := <-d.records
+	if ok {
+		return rec, nil
+	}
+	if d.err == nil {
+		if d.err = <-d.errc; d.err == nil {
+			d.err = io.EOF
+		}
+	}
+	return Record{}, d.err
+}
+
+// Close stops decoding. It must be called if the caller stops calling Next
+// before reaching io.EOF, so the background goroutine can exit.
+func (d *Decoder) Close() {
+	d.once.Do(func() { close(d.done) })
+}
+
+// decoderHandler is the Handler NewDecoder's background goroutine uses to
+// turn ParseStream's callbacks back into channel sends for Next.
+type decoderHandler struct {
+	d *Decoder
+}
+
+func (h decoderHandler) emit(rec Record) error {
+	select {
+	case h.d.records <- rec:
+		return nil
+	case <-h.d.done:
+		return ErrStop
+	}
+}
+
+func (h decoderHandler) OnUpdated(t time.Time) error {
+	return h.emit(Record{Type: RecordUpdated, Updated: t})
+}
+
+func (h decoderHandler) OnClient(c Client) error {
+	return h.emit(Record{Type: RecordClient, Client: c})
+}
+
+func (h decoderHandler) OnRoute(r Route) error {
+	return h.emit(Record{Type: RecordRoute, Route: r})
+}
+
+func (h decoderHandler) OnStats(s Stats) error {
+	return h.emit(Record{Type: RecordStats, Stats: s})
+}
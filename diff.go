@@ -0,0 +1,183 @@
+package openvpn
+
+import (
+	"context"
+	"time"
+)
+
+// ClientChange describes a client that was connected in both snapshots,
+// either because it reconnected (a new Since or a new RealAddress) or
+// because it simply transferred more data.
+type ClientChange struct {
+	Prev Client
+	Cur  Client
+
+	// BytesReceivedDelta and BytesSentDelta are Cur minus Prev. They are
+	// negative if the counters reset, e.g. because the client reconnected
+	// and the server started counting from zero again.
+	BytesReceivedDelta int64
+	BytesSentDelta     int64
+}
+
+// StatusDiff is the result of comparing two Status snapshots of the same
+// server taken at different times.
+type StatusDiff struct {
+	Connected    []Client
+	Disconnected []Client
+	Reconnected  []ClientChange
+
+	// Transferred holds a ClientChange for every client that was connected
+	// in both snapshots without reconnecting and whose byte counters
+	// changed, carrying its byte deltas. Clients with no change are omitted.
+	// Reconnected entries carry their own deltas instead and are never
+	// duplicated here.
+	Transferred []ClientChange
+
+	RoutesAdded   []Route
+	RoutesRemoved []Route
+}
+
+// Changed reports whether d contains any events at all. Watch uses this to
+// skip sending ticks where nothing happened.
+func (d StatusDiff) Changed() bool {
+	return len(d.Connected) > 0 || len(d.Disconnected) > 0 || len(d.Reconnected) > 0 ||
+		len(d.Transferred) > 0 || len(d.RoutesAdded) > 0 || len(d.RoutesRemoved) > 0
+}
+
+// Diff compares prev and cur, two Status snapshots of the same server, and
+// reports which clients connected, disconnected, reconnected, or merely
+// transferred more data, and which routes appeared or disappeared. Clients
+// are matched by CommonName; routes are matched by their virtual address
+// plus CommonName, since one CommonName routinely owns several routes
+// (IPv4, IPv6, iroute subnets). prev may be nil, in which case every client
+// and route in cur is reported as newly connected/added.
+func Diff(prev, cur *Status) StatusDiff {
+	var d StatusDiff
+	if prev == nil {
+		prev = &Status{}
+	}
+
+	prevClients := make(map[string]Client, len(prev.Clients))
+	for _, c := range prev.Clients {
+		prevClients[c.CommonName] = c
+	}
+	curClients := make(map[string]bool, len(cur.Clients))
+
+	for _, c := range cur.Clients {
+		curClients[c.CommonName] = true
+		p, ok := prevClients[c.CommonName]
+		if !ok {
+			d.Connected = append(d.Connected, c)
+			continue
+		}
+		change := ClientChange{
+			Prev:               p,
+			Cur:                c,
+			BytesReceivedDelta: int64(c.BytesReceived) - int64(p.BytesReceived),
+			BytesSentDelta:     int64(c.BytesSent) - int64(p.BytesSent),
+		}
+		if !p.Since.Equal(c.Since) || !addrEqual(p.RealAddress, c.RealAddress) {
+			d.Reconnected = append(d.Reconnected, change)
+		} else if change.BytesReceivedDelta != 0 || change.BytesSentDelta != 0 {
+			d.Transferred = append(d.Transferred, change)
+		}
+	}
+	for _, c := range prev.Clients {
+		if !curClients[c.CommonName] {
+			d.Disconnected = append(d.Disconnected, c)
+		}
+	}
+
+	prevRoutes := make(map[string]Route, len(prev.Routes))
+	for _, r := range prev.Routes {
+		prevRoutes[routeKey(r)] = r
+	}
+	curRoutes := make(map[string]bool, len(cur.Routes))
+	for _, r := range cur.Routes {
+		curRoutes[routeKey(r)] = true
+		if _, ok := prevRoutes[routeKey(r)]; !ok {
+			d.RoutesAdded = append(d.RoutesAdded, r)
+		}
+	}
+	for _, r := range prev.Routes {
+		if !curRoutes[routeKey(r)] {
+			d.RoutesRemoved = append(d.RoutesRemoved, r)
+		}
+	}
+
+	return d
+}
+
+func addrEqual(a, b Addr) bool {
+	return a.IP.Equal(b.IP) && a.Port == b.Port
+}
+
+// routeKey identifies a route by its virtual address, which is what
+// actually distinguishes routes belonging to the same CommonName (IPv4 vs.
+// IPv6, iroute subnets, ...).
+func routeKey(r Route) string {
+	return r.VirtualAddress.String() + "|" + r.CommonName
+}
+
+// Source is polled by Watch to obtain the current Status snapshot. A
+// ParseSource call (wrapped in a SourceFunc) or an *mgmt.Client's Status
+// method are both common implementations.
+type Source interface {
+	Fetch() (*Status, error)
+}
+
+// SourceFunc adapts a plain function to a Source.
+type SourceFunc func() (*Status, error)
+
+func (f SourceFunc) Fetch() (*Status, error) {
+	return f()
+}
+
+// Watch polls source every interval and sends a StatusDiff on the returned
+// channel whenever it differs from the previous successful poll. The first
+// successful poll only seeds the baseline snapshot and is never diffed
+// against anything, so it never appears on the channel as a mass-connect
+// event. A failed poll is skipped: it is neither diffed against nor
+// replaces the last good snapshot, so a single hiccup doesn't manufacture a
+// spurious mass disconnect either. The channel is closed when ctx is done.
+func Watch(ctx context.Context, source Source, interval time.Duration) <-chan StatusDiff {
+	ch := make(chan StatusDiff)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var prev *Status
+		var haveBaseline bool
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cur, err := source.Fetch()
+				if err != nil {
+					continue
+				}
+				if !haveBaseline {
+					prev, haveBaseline = cur, true
+					continue
+				}
+				d := Diff(prev, cur)
+				prev = cur
+				if !d.Changed() {
+					continue
+				}
+
+				select {
+				case ch <- d:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
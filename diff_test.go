@@ -0,0 +1,138 @@
+package openvpn_test
+
+import (
+	"context"
+	"net"
+	. "testing"
+	"time"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+func route(cn, vaddr string) openvpn.Route {
+	return openvpn.Route{CommonName: cn, VirtualAddress: openvpn.RouteAddr{IPNet: net.IPNet{IP: mustIP(vaddr), Mask: net.CIDRMask(32, 32)}}}
+}
+
+func TestDiff(t *T) {
+	prev := &openvpn.Status{
+		Clients: []openvpn.Client{
+			{CommonName: "stays", RealAddress: openvpn.Addr{IP: mustIP("1.1.1.1"), Port: 1}, BytesReceived: 100, Since: time.Unix(1, 0)},
+			{CommonName: "leaves", RealAddress: openvpn.Addr{IP: mustIP("2.2.2.2"), Port: 2}, Since: time.Unix(1, 0)},
+			{CommonName: "reconnects", RealAddress: openvpn.Addr{IP: mustIP("3.3.3.3"), Port: 3}, BytesReceived: 500, Since: time.Unix(1, 0)},
+			{CommonName: "transfers", RealAddress: openvpn.Addr{IP: mustIP("5.5.5.5"), Port: 5}, BytesReceived: 10, BytesSent: 20, Since: time.Unix(1, 0)},
+		},
+		Routes: []openvpn.Route{
+			route("stays", "10.0.0.1"),
+			// same CommonName as "stays" but a second, unrelated route
+			// (e.g. an IPv6 address) that disappears independently
+			route("stays", "fd00::1"),
+			route("removed-cn", "10.0.0.2"),
+		},
+	}
+	cur := &openvpn.Status{
+		Clients: []openvpn.Client{
+			{CommonName: "stays", RealAddress: openvpn.Addr{IP: mustIP("1.1.1.1"), Port: 1}, BytesReceived: 200, Since: time.Unix(1, 0)},
+			{CommonName: "reconnects", RealAddress: openvpn.Addr{IP: mustIP("3.3.3.3"), Port: 3}, BytesReceived: 50, Since: time.Unix(2, 0)},
+			{CommonName: "transfers", RealAddress: openvpn.Addr{IP: mustIP("5.5.5.5"), Port: 5}, BytesReceived: 30, BytesSent: 20, Since: time.Unix(1, 0)},
+			{CommonName: "arrives", RealAddress: openvpn.Addr{IP: mustIP("4.4.4.4"), Port: 4}},
+		},
+		Routes: []openvpn.Route{
+			route("stays", "10.0.0.1"),
+			// "stays" kept its CommonName but its second route changed to
+			// a new virtual address: must show up as removed+added, not
+			// as zero churn
+			route("stays", "10.0.0.9"),
+			route("added-cn", "10.0.0.3"),
+		},
+	}
+
+	d := openvpn.Diff(prev, cur)
+
+	if len(d.Connected) != 1 || d.Connected[0].CommonName != "arrives" {
+		t.Errorf("unexpected Connected: %+v", d.Connected)
+	}
+	if len(d.Disconnected) != 1 || d.Disconnected[0].CommonName != "leaves" {
+		t.Errorf("unexpected Disconnected: %+v", d.Disconnected)
+	}
+	if len(d.Reconnected) != 1 || d.Reconnected[0].Cur.CommonName != "reconnects" {
+		t.Errorf("unexpected Reconnected: %+v", d.Reconnected)
+	}
+	if d.Reconnected[0].BytesReceivedDelta != -450 {
+		t.Errorf("unexpected BytesReceivedDelta: %d", d.Reconnected[0].BytesReceivedDelta)
+	}
+	var transferred *openvpn.ClientChange
+	for i, c := range d.Transferred {
+		if c.Cur.CommonName == "transfers" {
+			transferred = &d.Transferred[i]
+		}
+	}
+	if len(d.Transferred) != 2 || transferred == nil {
+		t.Fatalf("unexpected Transferred: %+v", d.Transferred)
+	}
+	if transferred.BytesReceivedDelta != 20 || transferred.BytesSentDelta != 0 {
+		t.Errorf("unexpected Transferred deltas: %+v", transferred)
+	}
+	if len(d.RoutesAdded) != 2 {
+		t.Errorf("unexpected RoutesAdded: %+v", d.RoutesAdded)
+	}
+	if len(d.RoutesRemoved) != 2 {
+		t.Errorf("unexpected RoutesRemoved: %+v", d.RoutesRemoved)
+	}
+	if !d.Changed() {
+		t.Error("expected Changed to report true")
+	}
+}
+
+func TestDiffNoChange(t *T) {
+	s := &openvpn.Status{Clients: []openvpn.Client{{CommonName: "a", Since: time.Unix(1, 0)}}}
+	d := openvpn.Diff(s, s)
+	if d.Changed() {
+		t.Errorf("expected no changes, got: %+v", d)
+	}
+}
+
+func TestDiffNilPrev(t *T) {
+	cur := &openvpn.Status{Clients: []openvpn.Client{{CommonName: "a"}}}
+	d := openvpn.Diff(nil, cur)
+	if len(d.Connected) != 1 || len(d.Disconnected) != 0 {
+		t.Errorf("unexpected diff against nil prev: %+v", d)
+	}
+}
+
+func TestWatch(t *T) {
+	statuses := []*openvpn.Status{
+		{Clients: []openvpn.Client{{CommonName: "a"}}}, // baseline; must not be reported as a connect
+		{Clients: []openvpn.Client{{CommonName: "a"}}}, // unchanged; should produce no tick
+		{Clients: []openvpn.Client{{CommonName: "a"}, {CommonName: "b"}}},
+	}
+	var i int
+	source := openvpn.SourceFunc(func() (*openvpn.Status, error) {
+		s := statuses[i]
+		if i < len(statuses)-1 {
+			i++
+		}
+		return s, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := openvpn.Watch(ctx, source, 5*time.Millisecond)
+
+	first := <-ch
+	if len(first.Connected) != 1 || first.Connected[0].CommonName != "b" {
+		t.Fatalf("unexpected first diff: %+v", first)
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel to close after ctx cancellation")
+	}
+}
+
+func mustIP(s string) net.IP {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		panic("bad ip: " + s)
+	}
+	return ip
+}
@@ -0,0 +1,83 @@
+// Package exporter turns an openvpn.Status into Prometheus/OpenMetrics
+// text-format metrics, for scraping an OpenVPN server's client/route table.
+package exporter
+
+import (
+	"net/http"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+// Fetcher returns the Status to export on a scrape. Implementations
+// typically close over an openvpn.ParseSource call (re-parsing a file or
+// URL on every scrape) or an *mgmt.Client (re-running "status 2" against a
+// live management interface).
+type Fetcher interface {
+	Fetch() (*openvpn.Status, error)
+}
+
+// FetcherFunc adapts a plain function to a Fetcher.
+type FetcherFunc func() (*openvpn.Status, error)
+
+func (f FetcherFunc) Fetch() (*openvpn.Status, error) {
+	return f()
+}
+
+// NewHandler returns an http.Handler that calls source on every scrape and
+// writes its Status as Prometheus text-format metrics. If source returns an
+// error, the handler still responds 200 with openvpn_up set to 0, which is
+// the usual convention for exporters: a scrape failure is data, not an
+// outage of the exporter itself.
+func NewHandler(source Fetcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		s, err := source.Fetch()
+		if err != nil {
+			writeGauge(w, "openvpn_up", nil, 0)
+			return
+		}
+		writeGauge(w, "openvpn_up", nil, 1)
+		writeStatus(w, s)
+	})
+}
+
+func writeStatus(w http.ResponseWriter, s *openvpn.Status) {
+	updated := s.Updated
+	if updated.IsZero() {
+		updated = s.Time
+	}
+	if !updated.IsZero() {
+		writeGauge(w, "openvpn_status_update_time_seconds", nil, float64(updated.Unix()))
+	}
+	writeGauge(w, "openvpn_server_max_bcast_mcast_queue_length", nil, float64(s.MaxQueue))
+
+	for _, c := range s.Clients {
+		labels := clientLabels(c)
+		writeCounter(w, "openvpn_client_bytes_received_total", labels, float64(c.BytesReceived))
+		writeCounter(w, "openvpn_client_bytes_sent_total", labels, float64(c.BytesSent))
+		if !c.Since.IsZero() {
+			writeGauge(w, "openvpn_client_connected_since_seconds", labels, float64(c.Since.Unix()))
+		}
+	}
+
+	for _, route := range s.Routes {
+		labels := []label{
+			{"common_name", route.CommonName},
+			{"real_address", route.RealAddress.IP.String()},
+			{"virtual_address", route.VirtualAddress.String()},
+		}
+		writeGauge(w, "openvpn_route", labels, 1)
+	}
+}
+
+func clientLabels(c openvpn.Client) []label {
+	labels := []label{
+		{"common_name", c.CommonName},
+		{"real_address", c.RealAddress.IP.String()},
+	}
+	if c.VirtualAddress != nil {
+		labels = append(labels, label{"virtual_address", c.VirtualAddress.String()})
+	}
+	return labels
+}
@@ -0,0 +1,83 @@
+package exporter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	. "testing"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+const fixture = `OpenVPN CLIENT LIST
+Updated,Thu Nov  5 15:34:43 2015
+Common Name,Real Address,Bytes Received,Bytes Sent,Connected Since
+test1,6.6.6.6:1000,100,98,Thu Nov  5 15:34:43 2015
+ROUTING TABLE
+Virtual Address,Common Name,Real Address,Last Ref
+10.0.0.1,test1,6.6.6.6:1000,Thu Nov  5 15:34:43 2015
+GLOBAL STATS
+Max bcast / mcast queue length,39
+END`
+
+func TestNewHandler(t *T) {
+	s, err := openvpn.Parse(strings.NewReader(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(FetcherFunc(func() (*openvpn.Status, error) { return s, nil }))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(body)
+
+	for _, want := range []string{
+		"openvpn_up 1",
+		"openvpn_server_max_bcast_mcast_queue_length 39",
+		`openvpn_client_bytes_received_total{common_name="test1",real_address="6.6.6.6"} 100`,
+		`openvpn_client_bytes_sent_total{common_name="test1",real_address="6.6.6.6"} 98`,
+		`openvpn_route{common_name="test1",real_address="6.6.6.6",virtual_address="10.0.0.1/32"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestNewHandlerFetchError(t *T) {
+	h := NewHandler(FetcherFunc(func() (*openvpn.Status, error) {
+		return nil, errFake
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 even on fetch error, got %d", resp.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "openvpn_up 0") {
+		t.Errorf("expected openvpn_up 0, got:\n%s", body)
+	}
+}
+
+var errFake = fakeErr("fake fetch error")
+
+type fakeErr string
+
+func (e fakeErr) Error() string { return string(e) }
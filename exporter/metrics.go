@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// label is a single Prometheus label name/value pair.
+type label struct {
+	name, value string
+}
+
+// writeGauge and writeCounter both just write a metric line; the
+// distinction is documentation for readers of this code only. No "# TYPE"
+// line is written, so the exposed output does not say which kind a metric is.
+func writeGauge(w io.Writer, name string, labels []label, value float64) {
+	writeMetric(w, name, labels, value)
+}
+
+func writeCounter(w io.Writer, name string, labels []label, value float64) {
+	writeMetric(w, name, labels, value)
+}
+
+func writeMetric(w io.Writer, name string, labels []label, value float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(labels), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = fmt.Sprintf(`%s="%s"`, l.name, escapeLabelValue(l.value))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// escapeLabelValue escapes a string for use inside a Prometheus label value,
+// per https://prometheus.io/docs/instrumenting/exposition_formats/
+func escapeLabelValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	return v
+}
@@ -0,0 +1,116 @@
+package mgmt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+// Status runs the management interface's "status" command and parses the
+// result with openvpn.Parse. version must be 2 or 3, matching the
+// --status-version formats Parse understands.
+func (c *Client) Status(version int) (*openvpn.Status, error) {
+	if version != 2 && version != 3 {
+		return nil, fmt.Errorf("mgmt: unsupported status version %d", version)
+	}
+	lines, err := c.sendCommand(fmt.Sprintf("status %d", version), true)
+	if err != nil {
+		return nil, err
+	}
+	return openvpn.Parse(strings.NewReader(strings.Join(lines, "\n")))
+}
+
+// Kill disconnects every client whose common name is cn.
+func (c *Client) Kill(cn string) error {
+	_, err := c.sendCommand("kill "+cn, false)
+	return err
+}
+
+// ClientKill disconnects the client with the given management client ID
+// (CID), as reported in a ClientEvent. message, if non-empty, is passed to
+// the client before it's disconnected.
+func (c *Client) ClientKill(cid, message string) error {
+	cmd := "client-kill " + cid
+	if message != "" {
+		cmd += " " + message
+	}
+	_, err := c.sendCommand(cmd, false)
+	return err
+}
+
+// LoadStats runs "load-stats" and returns its key/value pairs, e.g.
+// "nclients" and "bytesin"/"bytesout", as reported by the server.
+func (c *Client) LoadStats() (map[string]uint64, error) {
+	lines, err := c.sendCommand("load-stats", false)
+	if err != nil {
+		return nil, err
+	}
+	stats := map[string]uint64{}
+	if len(lines) == 0 {
+		return stats, nil
+	}
+	for _, kv := range strings.Split(lines[0], ",") {
+		k, v, ok := splitKV(kv)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		stats[k] = n
+	}
+	return stats, nil
+}
+
+// PID returns the process ID of the running openvpn instance.
+func (c *Client) PID() (int, error) {
+	lines, err := c.sendCommand("pid", false)
+	if err != nil {
+		return 0, err
+	}
+	if len(lines) == 0 {
+		return 0, errors.New("mgmt: empty pid reply")
+	}
+	_, v, ok := splitKV(lines[0])
+	if !ok {
+		return 0, fmt.Errorf("mgmt: unexpected pid reply: %s", lines[0])
+	}
+	return strconv.Atoi(v)
+}
+
+// Version returns the server's "version" banner.
+func (c *Client) Version() (string, error) {
+	lines, err := c.sendCommand("version", true)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// HoldRelease releases a management hold, letting the server continue
+// starting up. It's a no-op if the server isn't currently on hold.
+func (c *Client) HoldRelease() error {
+	_, err := c.sendCommand("hold release", false)
+	return err
+}
+
+// splitKV splits a "key=value" string
+func splitKV(s string) (key, value string, ok bool) {
+	idx := strings.Index(s, "=")
+	if idx < 0 {
+		return "", "", false
+	}
+	return s[:idx], s[idx+1:], true
+}
+
+func parseUintOrZero(s string) uint64 {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,168 @@
+package mgmt
+
+import "strings"
+
+// ClientEvent represents an asynchronous ">CLIENT:..." notification, sent
+// as clients connect, authenticate, and disconnect.
+type ClientEvent struct {
+	// Type is the CLIENT sub-message, e.g. CONNECT, REAUTH, ESTABLISHED,
+	// ADDRESS, DISCONNECT, UPDATE, or ENV
+	Type   string
+	Fields []string
+}
+
+// ByteCountEvent represents an asynchronous ">BYTECOUNT:..." (or, under
+// management-client-auth, ">BYTECOUNT_CLI:...") notification.
+type ByteCountEvent struct {
+	// CID is only set for the per-client BYTECOUNT_CLI variant
+	CID           string
+	BytesReceived uint64
+	BytesSent     uint64
+}
+
+// StateEvent represents an asynchronous ">STATE:..." notification describing
+// an OpenVPN connection state transition.
+type StateEvent struct {
+	Fields []string
+}
+
+// LogEvent represents an asynchronous ">LOG:..." notification.
+type LogEvent struct {
+	Fields []string
+}
+
+// HoldEvent represents an asynchronous ">HOLD:..." notification, sent when
+// the process is waiting on a "hold release" command before it continues.
+type HoldEvent struct {
+	Fields []string
+}
+
+// OnClient registers fn to be called for every CLIENT notification received
+// for the lifetime of the connection.
+func (c *Client) OnClient(fn func(ClientEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onClient = append(c.onClient, fn)
+}
+
+// OnByteCount registers fn to be called for every BYTECOUNT notification.
+func (c *Client) OnByteCount(fn func(ByteCountEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onByteCount = append(c.onByteCount, fn)
+}
+
+// OnState registers fn to be called for every STATE notification.
+func (c *Client) OnState(fn func(StateEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onState = append(c.onState, fn)
+}
+
+// OnLog registers fn to be called for every LOG notification.
+func (c *Client) OnLog(fn func(LogEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onLog = append(c.onLog, fn)
+}
+
+// OnHold registers fn to be called for every HOLD notification.
+func (c *Client) OnHold(fn func(HoldEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onHold = append(c.onHold, fn)
+}
+
+// dispatch parses a raw ">TYPE:rest" notification line and queues any
+// callbacks registered for it to run on the dispatch goroutine. It never
+// calls a callback itself and never holds c.mu while one runs, so a
+// callback is free to issue a command or register another callback without
+// deadlocking against readLoop.
+func (c *Client) dispatch(line string) {
+	body := strings.TrimPrefix(line, ">")
+	idx := strings.Index(body, ":")
+	if idx < 0 {
+		return
+	}
+	typ, rest := body[:idx], body[idx+1:]
+	fields := strings.Split(rest, ",")
+
+	c.mu.Lock()
+	switch typ {
+	case "CLIENT":
+		if len(fields) == 0 {
+			c.mu.Unlock()
+			return
+		}
+		ev := ClientEvent{Type: fields[0], Fields: fields[1:]}
+		fns := append([]func(ClientEvent){}, c.onClient...)
+		c.mu.Unlock()
+		c.dispatchQueue() <- func() {
+			for _, fn := range fns {
+				fn(ev)
+			}
+		}
+	case "BYTECOUNT":
+		if len(fields) != 2 {
+			c.mu.Unlock()
+			return
+		}
+		ev := ByteCountEvent{
+			BytesReceived: parseUintOrZero(fields[0]),
+			BytesSent:     parseUintOrZero(fields[1]),
+		}
+		fns := append([]func(ByteCountEvent){}, c.onByteCount...)
+		c.mu.Unlock()
+		c.dispatchQueue() <- func() {
+			for _, fn := range fns {
+				fn(ev)
+			}
+		}
+	case "BYTECOUNT_CLI":
+		if len(fields) != 3 {
+			c.mu.Unlock()
+			return
+		}
+		ev := ByteCountEvent{
+			CID:           fields[0],
+			BytesReceived: parseUintOrZero(fields[1]),
+			BytesSent:     parseUintOrZero(fields[2]),
+		}
+		fns := append([]func(ByteCountEvent){}, c.onByteCount...)
+		c.mu.Unlock()
+		c.dispatchQueue() <- func() {
+			for _, fn := range fns {
+				fn(ev)
+			}
+		}
+	case "STATE":
+		ev := StateEvent{Fields: fields}
+		fns := append([]func(StateEvent){}, c.onState...)
+		c.mu.Unlock()
+		c.dispatchQueue() <- func() {
+			for _, fn := range fns {
+				fn(ev)
+			}
+		}
+	case "LOG":
+		ev := LogEvent{Fields: fields}
+		fns := append([]func(LogEvent){}, c.onLog...)
+		c.mu.Unlock()
+		c.dispatchQueue() <- func() {
+			for _, fn := range fns {
+				fn(ev)
+			}
+		}
+	case "HOLD":
+		ev := HoldEvent{Fields: fields}
+		fns := append([]func(HoldEvent){}, c.onHold...)
+		c.mu.Unlock()
+		c.dispatchQueue() <- func() {
+			for _, fn := range fns {
+				fn(ev)
+			}
+		}
+	default:
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,175 @@
+// Package mgmt speaks OpenVPN's management protocol, letting callers
+// interact with a running openvpn process over a TCP or unix socket instead
+// of polling its status file.
+package mgmt
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// dispatchBufferSize bounds how many pending notification dispatches may
+// queue up behind a slow callback before readLoop itself starts blocking.
+const dispatchBufferSize = 256
+
+// Client is a connection to an OpenVPN management interface.
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	// writeMu serializes writes to conn and guards pending, since the
+	// management protocol only allows one command to be in flight at a time
+	writeMu sync.Mutex
+	pending chan string
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	// dispatchCh decouples running notification callbacks from readLoop:
+	// dispatch enqueues a func here instead of calling it directly, so a
+	// callback that issues a command (or registers another callback) can't
+	// deadlock waiting on the very readLoop that would run it. It's lazily
+	// initialized by dispatchQueue so dispatch() can use it even if it runs
+	// before readLoop has had a chance to start the worker goroutine.
+	dispatchOnce sync.Once
+	dispatchCh   chan func()
+
+	mu          sync.Mutex
+	onClient    []func(ClientEvent)
+	onByteCount []func(ByteCountEvent)
+	onState     []func(StateEvent)
+	onLog       []func(LogEvent)
+	onHold      []func(HoldEvent)
+}
+
+// Dial connects to an OpenVPN management interface listening on network/
+// address, e.g. Dial("tcp", "127.0.0.1:7505") or Dial("unix", "/run/openvpn.sock").
+func Dial(network, address string) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:   conn,
+		r:      bufio.NewReader(conn),
+		closed: make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// Close closes the underlying connection. Any command blocked waiting on a
+// reply returns an error.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.conn.Close()
+}
+
+// Done returns a channel that's closed once the connection has been lost or
+// closed, for callers (e.g. a reconnect loop) that want to wait on it.
+func (c *Client) Done() <-chan struct{} {
+	return c.closed
+}
+
+// readLoop reads lines off the connection for the lifetime of the client,
+// dispatching asynchronous ">..." notifications as they arrive and handing
+// everything else to whichever command is currently waiting on a reply.
+func (c *Client) readLoop() {
+	c.dispatchQueue()
+
+	for {
+		line, err := c.r.ReadString('\n')
+		if text := strings.TrimRight(line, "\r\n"); text != "" {
+			c.handleLine(text)
+		}
+		if err != nil {
+			c.Close()
+			c.writeMu.Lock()
+			if c.pending != nil {
+				close(c.pending)
+				c.pending = nil
+			}
+			c.writeMu.Unlock()
+			close(c.dispatchCh)
+			return
+		}
+	}
+}
+
+// dispatchQueue returns the channel notification dispatches are queued on,
+// starting its worker goroutine the first time it's called. It's safe to
+// call from both readLoop and dispatch: whichever gets there first does the
+// one-time setup.
+func (c *Client) dispatchQueue() chan func() {
+	c.dispatchOnce.Do(func() {
+		c.dispatchCh = make(chan func(), dispatchBufferSize)
+		go func() {
+			for fn := range c.dispatchCh {
+				fn()
+			}
+		}()
+	})
+	return c.dispatchCh
+}
+
+func (c *Client) handleLine(line string) {
+	if strings.HasPrefix(line, ">") {
+		c.dispatch(line)
+		return
+	}
+	c.writeMu.Lock()
+	ch := c.pending
+	c.writeMu.Unlock()
+	if ch != nil {
+		ch <- line
+	}
+}
+
+// sendCommand writes cmd to the management interface and waits for its
+// reply. If multiline is true the reply is read until a trailing "END"
+// line, as with "status" or "version"; otherwise a single "SUCCESS:"/
+// "ERROR:" line is expected, as with "kill" or "pid".
+func (c *Client) sendCommand(cmd string, multiline bool) ([]string, error) {
+	c.writeMu.Lock()
+	if c.pending != nil {
+		c.writeMu.Unlock()
+		return nil, errors.New("mgmt: a command is already in flight")
+	}
+	ch := make(chan string, 16)
+	c.pending = ch
+	_, err := c.conn.Write([]byte(cmd + "\n"))
+	c.writeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for line := range ch {
+		if strings.HasPrefix(line, "ERROR:") {
+			c.clearPending()
+			return nil, errors.New(strings.TrimSpace(strings.TrimPrefix(line, "ERROR:")))
+		}
+		if !multiline {
+			c.clearPending()
+			if strings.HasPrefix(line, "SUCCESS:") {
+				return []string{strings.TrimSpace(strings.TrimPrefix(line, "SUCCESS:"))}, nil
+			}
+			return []string{line}, nil
+		}
+		if line == "END" {
+			c.clearPending()
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+	return nil, errors.New("mgmt: connection closed while waiting for a reply")
+}
+
+func (c *Client) clearPending() {
+	c.writeMu.Lock()
+	c.pending = nil
+	c.writeMu.Unlock()
+}
@@ -0,0 +1,154 @@
+package mgmt
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	. "testing"
+	"time"
+)
+
+// fakeServer serves a single client connection from one side of a net.Pipe,
+// replying to each line it reads with the canned response replies maps that
+// line to, followed by "\n".
+func fakeServer(t *T, conn net.Conn, replies map[string]string) {
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" && err != nil {
+			return
+		}
+		reply, ok := replies[line]
+		if !ok {
+			t.Errorf("server: unexpected command %q", line)
+			return
+		}
+		if _, err := conn.Write([]byte(reply + "\n")); err != nil {
+			return
+		}
+	}
+}
+
+func newTestClient(t *T, replies map[string]string) *Client {
+	serverConn, clientConn := net.Pipe()
+	go fakeServer(t, serverConn, replies)
+
+	c := &Client{conn: clientConn, r: bufio.NewReader(clientConn), closed: make(chan struct{})}
+	go c.readLoop()
+	return c
+}
+
+func TestClientStatus(t *T) {
+	status := "OpenVPN CLIENT LIST\n" +
+		"Updated,Thu Nov  5 15:34:43 2015\n" +
+		"Common Name,Real Address,Bytes Received,Bytes Sent,Connected Since\n" +
+		"test1,6.6.6.6:1000,100,98,Thu Nov  5 15:34:43 2015\n" +
+		"ROUTING TABLE\n" +
+		"GLOBAL STATS\n" +
+		"Max bcast / mcast queue length,39\n" +
+		"END"
+
+	c := newTestClient(t, map[string]string{
+		"status 2": status,
+	})
+	defer c.Close()
+
+	s, err := c.Status(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(s.Clients) != 1 || s.Clients[0].CommonName != "test1" {
+		t.Fatalf("unexpected clients: %+v", s.Clients)
+	}
+}
+
+func TestClientKill(t *T) {
+	c := newTestClient(t, map[string]string{
+		"kill test1": "SUCCESS: common name 'test1' found, 1 client(s) killed",
+		"kill test2": "ERROR: common name 'test2' not found",
+	})
+	defer c.Close()
+
+	if err := c.Kill("test1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := c.Kill("test2"); err == nil {
+		t.Fatal("expected an error killing an unknown client")
+	}
+}
+
+func TestClientNotifications(t *T) {
+	serverConn, clientConn := net.Pipe()
+	c := &Client{conn: clientConn, r: bufio.NewReader(clientConn), closed: make(chan struct{})}
+	go c.readLoop()
+	defer c.Close()
+
+	events := make(chan ClientEvent, 1)
+	c.OnClient(func(ev ClientEvent) { events <- ev })
+
+	go func() {
+		serverConn.Write([]byte(">CLIENT:CONNECT,1,2\n"))
+	}()
+
+	ev := <-events
+	if ev.Type != "CONNECT" {
+		t.Fatalf("unexpected event type: %q", ev.Type)
+	}
+	if len(ev.Fields) != 2 || ev.Fields[0] != "1" || ev.Fields[1] != "2" {
+		t.Fatalf("unexpected event fields: %+v", ev.Fields)
+	}
+}
+
+// TestCallbackCanIssueCommand guards against a callback deadlocking: if a
+// notification callback ran on readLoop itself, calling PID (which needs
+// readLoop to read its reply) would hang forever.
+func TestCallbackCanIssueCommand(t *T) {
+	c := newTestClient(t, map[string]string{
+		"pid": "SUCCESS: pid=1234",
+	})
+	defer c.Close()
+
+	done := make(chan int, 1)
+	c.OnClient(func(ev ClientEvent) {
+		pid, err := c.PID()
+		if err != nil {
+			t.Errorf("unexpected error calling PID from a callback: %s", err)
+			return
+		}
+		done <- pid
+	})
+
+	go c.handleLine(">CLIENT:CONNECT,1,2")
+
+	select {
+	case pid := <-done:
+		if pid != 1234 {
+			t.Fatalf("unexpected pid: %d", pid)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out: callback issuing a command deadlocked")
+	}
+}
+
+// TestCallbackCanRegisterCallback guards against the related deadlock: a
+// callback registering another callback via OnX must not block on c.mu,
+// which dispatch would still be holding if callbacks ran under the lock.
+func TestCallbackCanRegisterCallback(t *T) {
+	c := newTestClient(t, nil)
+	defer c.Close()
+
+	done := make(chan struct{}, 1)
+	c.OnClient(func(ClientEvent) {
+		c.OnLog(func(LogEvent) {})
+		close(done)
+	})
+
+	go c.handleLine(">CLIENT:CONNECT,1,2")
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out: callback registering a callback deadlocked")
+	}
+}
@@ -0,0 +1,156 @@
+package mgmt
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Backoff configures the delay schedule DialRetry uses between reconnect
+// attempts: it starts at Min and doubles on every consecutive failure, up
+// to Max.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (b Backoff) delay(attempt int) time.Duration {
+	d := b.Min
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// ReconnectingClient wraps Dial with an automatic reconnect/backoff loop,
+// since a polling or monitoring process typically wants to survive the
+// management socket (and the openvpn process behind it) going away and
+// coming back.
+type ReconnectingClient struct {
+	network, address string
+	backoff          Backoff
+
+	mu            sync.Mutex
+	current       *Client
+	registrations []func(*Client)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// DialRetry starts a reconnect loop against network/address, dialing
+// immediately and redialing with the given backoff whenever the connection
+// is lost. Use Client to access the current connection and OnClient/
+// OnState/etc to register notification callbacks that survive reconnects.
+func DialRetry(network, address string, backoff Backoff) *ReconnectingClient {
+	rc := &ReconnectingClient{
+		network: network,
+		address: address,
+		backoff: backoff,
+		stop:    make(chan struct{}),
+	}
+	go rc.run()
+	return rc
+}
+
+func (rc *ReconnectingClient) run() {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-rc.stop:
+			return
+		default:
+		}
+
+		c, err := Dial(rc.network, rc.address)
+		if err != nil {
+			select {
+			case <-rc.stop:
+				return
+			case <-time.After(rc.backoff.delay(attempt)):
+				continue
+			}
+		}
+		attempt = -1 // reset on the next iteration if this connection also fails
+
+		rc.mu.Lock()
+		rc.current = c
+		for _, apply := range rc.registrations {
+			apply(c)
+		}
+		rc.mu.Unlock()
+
+		select {
+		case <-c.Done():
+		case <-rc.stop:
+			c.Close()
+			return
+		}
+
+		rc.mu.Lock()
+		rc.current = nil
+		rc.mu.Unlock()
+	}
+}
+
+// Client returns the currently connected Client, or an error if a
+// connection isn't currently established.
+func (rc *ReconnectingClient) Client() (*Client, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.current == nil {
+		return nil, errors.New("mgmt: not currently connected")
+	}
+	return rc.current, nil
+}
+
+// Close stops the reconnect loop and closes the current connection, if any.
+func (rc *ReconnectingClient) Close() error {
+	rc.stopOnce.Do(func() { close(rc.stop) })
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.current != nil {
+		return rc.current.Close()
+	}
+	return nil
+}
+
+// register saves apply for replay against future connections, and also
+// applies it to the current one if there is one.
+func (rc *ReconnectingClient) register(apply func(*Client)) {
+	rc.mu.Lock()
+	rc.registrations = append(rc.registrations, apply)
+	c := rc.current
+	rc.mu.Unlock()
+	if c != nil {
+		apply(c)
+	}
+}
+
+// OnClient registers fn to be called for every CLIENT notification on every
+// connection made by this ReconnectingClient, current and future.
+func (rc *ReconnectingClient) OnClient(fn func(ClientEvent)) {
+	rc.register(func(c *Client) { c.OnClient(fn) })
+}
+
+// OnByteCount registers fn to be called for every BYTECOUNT notification.
+func (rc *ReconnectingClient) OnByteCount(fn func(ByteCountEvent)) {
+	rc.register(func(c *Client) { c.OnByteCount(fn) })
+}
+
+// OnState registers fn to be called for every STATE notification.
+func (rc *ReconnectingClient) OnState(fn func(StateEvent)) {
+	rc.register(func(c *Client) { c.OnState(fn) })
+}
+
+// OnLog registers fn to be called for every LOG notification.
+func (rc *ReconnectingClient) OnLog(fn func(LogEvent)) {
+	rc.register(func(c *Client) { c.OnLog(fn) })
+}
+
+// OnHold registers fn to be called for every HOLD notification.
+func (rc *ReconnectingClient) OnHold(fn func(HoldEvent)) {
+	rc.register(func(c *Client) { c.OnHold(fn) })
+}
@@ -0,0 +1,208 @@
+package openvpn
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// FetchOptions carries the settings ParseSource's Options configure through
+// to whichever Fetcher handles the URI's scheme. A Fetcher is free to
+// ignore whichever fields don't apply to it.
+type FetchOptions struct {
+	Timeout time.Duration
+
+	BasicUser, BasicPass string
+	BearerToken          string
+
+	// TLSConfig is used for the https:// fetcher, and is how callers supply
+	// a client certificate for mTLS.
+	TLSConfig *tls.Config
+}
+
+// Option configures a FetchOptions used by ParseSource.
+type Option func(*FetchOptions)
+
+// WithTimeout bounds how long fetching the source may take.
+func WithTimeout(d time.Duration) Option {
+	return func(o *FetchOptions) { o.Timeout = d }
+}
+
+// WithBasicAuth sets the username/password an http(s):// fetch authenticates
+// with.
+func WithBasicAuth(user, pass string) Option {
+	return func(o *FetchOptions) { o.BasicUser, o.BasicPass = user, pass }
+}
+
+// WithBearerToken sets the bearer token an http(s):// fetch authenticates
+// with.
+func WithBearerToken(token string) Option {
+	return func(o *FetchOptions) { o.BearerToken = token }
+}
+
+// WithTLSConfig sets the tls.Config an https:// fetch dials with, e.g. to
+// present a client certificate for mutual TLS.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *FetchOptions) { o.TLSConfig = cfg }
+}
+
+// Fetcher retrieves the raw status log a URI refers to. RegisterFetcher
+// lets callers add support for schemes ParseSource doesn't know natively,
+// e.g. "s3://" or "ssh://".
+type Fetcher interface {
+	Fetch(uri string, opts FetchOptions) (io.ReadCloser, error)
+}
+
+// FetcherFunc adapts a plain function to a Fetcher.
+type FetcherFunc func(uri string, opts FetchOptions) (io.ReadCloser, error)
+
+func (f FetcherFunc) Fetch(uri string, opts FetchOptions) (io.ReadCloser, error) {
+	return f(uri, opts)
+}
+
+var fetchers = map[string]Fetcher{
+	"file":  FetcherFunc(fetchFile),
+	"http":  FetcherFunc(fetchHTTP),
+	"https": FetcherFunc(fetchHTTP),
+	"tcp":   FetcherFunc(fetchManagement),
+}
+
+// RegisterFetcher registers f to handle URIs with the given scheme,
+// replacing any Fetcher already registered for it.
+func RegisterFetcher(scheme string, f Fetcher) {
+	fetchers[scheme] = f
+}
+
+// UnregisterFetcher removes the Fetcher registered for scheme, if any,
+// reverting ParseSource to returning an error for that scheme. It undoes a
+// prior RegisterFetcher call, e.g. in test teardown.
+func UnregisterFetcher(scheme string) {
+	delete(fetchers, scheme)
+}
+
+// ParseSource fetches a status log from uri and parses it with Parse. The
+// scheme determines how it's fetched: "file://" reads a local file,
+// "http://"/"https://" fetch it over HTTP, and "tcp://" treats the URI as an
+// OpenVPN management interface address and runs "status 2" against it.
+// Additional schemes can be added with RegisterFetcher.
+func ParseSource(uri string, opts ...Option) (*Status, error) {
+	var o FetchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := fetchers[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("openvpn: no fetcher registered for scheme %q", u.Scheme)
+	}
+
+	rc, err := f.Fetch(uri, o)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return Parse(rc)
+}
+
+func fetchFile(uri string, opts FetchOptions) (io.ReadCloser, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	path := u.Path
+	if path == "" {
+		path = u.Opaque
+	}
+	return os.Open(path)
+}
+
+func fetchHTTP(uri string, opts FetchOptions) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.BearerToken)
+	} else if opts.BasicUser != "" || opts.BasicPass != "" {
+		req.SetBasicAuth(opts.BasicUser, opts.BasicPass)
+	}
+
+	client := &http.Client{Timeout: opts.Timeout}
+	if opts.TLSConfig != nil {
+		client.Transport = &http.Transport{TLSClientConfig: opts.TLSConfig}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("openvpn: %s returned %s", uri, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// fetchManagement treats uri's host:port as an OpenVPN management interface
+// and runs "status 2" against it. For ongoing monitoring or control,
+// openvpn/mgmt.Dial is a better fit; this exists for one-off scrapes.
+func fetchManagement(uri string, opts FetchOptions) (io.ReadCloser, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: opts.Timeout}
+	conn, err := dialer.Dial("tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if opts.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(opts.Timeout))
+	}
+	if _, err := conn.Write([]byte("status 2\n")); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	buf := make([]byte, 0, 4096)
+	chunk := make([]byte, 4096)
+	for {
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		for {
+			idx := bytes.IndexByte(buf, '\n')
+			if idx < 0 {
+				break
+			}
+			line := strings.TrimRight(string(buf[:idx]), "\r")
+			buf = buf[idx+1:]
+			if strings.HasPrefix(line, ">") {
+				// an asynchronous notification interleaved with our reply;
+				// ignore it, we only care about the status output
+				continue
+			}
+			if line == "END" {
+				return ioutil.NopCloser(strings.NewReader(strings.Join(lines, "\n"))), nil
+			}
+			lines = append(lines, line)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package openvpn_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	. "testing"
+	"time"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+func TestParseSourceFile(t *T) {
+	f, err := ioutil.TempFile("", "openvpn-status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(v1Fixture); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s, err := openvpn.ParseSource("file://" + f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(s.Clients) != 1 {
+		t.Fatalf("unexpected clients: %+v", s.Clients)
+	}
+}
+
+func TestParseSourceHTTP(t *T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "admin" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(v1Fixture))
+	}))
+	defer srv.Close()
+
+	if _, err := openvpn.ParseSource(srv.URL); err == nil {
+		t.Fatal("expected an error without credentials")
+	}
+
+	s, err := openvpn.ParseSource(srv.URL, openvpn.WithBasicAuth("admin", "secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(s.Clients) != 1 {
+		t.Fatalf("unexpected clients: %+v", s.Clients)
+	}
+}
+
+func TestParseSourceManagement(t *T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		cmd, _ := r.ReadString('\n')
+		if strings.TrimSpace(cmd) != "status 2" {
+			return
+		}
+		conn.Write([]byte(">NOTIFY:ignored\n"))
+		conn.Write([]byte(v1Fixture + "\n"))
+	}()
+
+	s, err := openvpn.ParseSource("tcp://"+ln.Addr().String(), openvpn.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(s.Clients) != 1 {
+		t.Fatalf("unexpected clients: %+v", s.Clients)
+	}
+}
+
+func TestParseSourceUnknownScheme(t *T) {
+	_, err := openvpn.ParseSource("s3://bucket/key")
+	if err == nil {
+		t.Fatal("expected an error for an unregistered scheme")
+	}
+}
+
+func TestRegisterFetcher(t *T) {
+	var called string
+	openvpn.RegisterFetcher("test", openvpn.FetcherFunc(func(uri string, opts openvpn.FetchOptions) (io.ReadCloser, error) {
+		called = uri
+		return ioutil.NopCloser(strings.NewReader(v1Fixture)), nil
+	}))
+	defer openvpn.UnregisterFetcher("test")
+
+	s, err := openvpn.ParseSource("test://somewhere")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if called != "test://somewhere" {
+		t.Fatalf("custom fetcher wasn't invoked with the right uri, got %q", called)
+	}
+	if len(s.Clients) != 1 {
+		t.Fatalf("unexpected clients: %+v", s.Clients)
+	}
+}
@@ -37,6 +37,16 @@ type Client struct {
 	BytesReceived uint64
 	BytesSent     uint64
 	Since         time.Time
+
+	// The following fields are only populated when the status log was
+	// generated with --status-version 2 or 3. They are left at their
+	// zero value for version 1 logs.
+	VirtualAddress     net.IP
+	VirtualIPv6Address net.IP
+	Username           string
+	ClientID           uint64
+	PeerID             uint64
+	DataChannelCipher  string
 }
 
 // Represents a OpenVPN route
@@ -60,6 +70,11 @@ const (
 
 // Represents the OpenVPN status at a point in time
 type Status struct {
+	// Title and Time come from the TITLE/TIME records emitted by status
+	// versions 2 and 3. They are left at their zero value for version 1
+	// logs, which do not include them.
+	Title    string
+	Time     time.Time
 	Updated  time.Time
 	Clients  []Client
 	Routes   []Route
@@ -67,9 +82,40 @@ type Status struct {
 	state    readState
 }
 
+// sink is what the line parsers below feed parsed records into. *Status
+// implements it directly so Parse can accumulate everything in memory;
+// ParseStream adapts an arbitrary Handler to it so records can be streamed
+// straight to the caller instead.
+type sink interface {
+	setUpdated(time.Time) error
+	addClient(Client) error
+	addRoute(Route) error
+	setStats(Stats) error
+}
+
+func (s *Status) setUpdated(t time.Time) error {
+	s.Updated = t
+	return nil
+}
+
+func (s *Status) addClient(c Client) error {
+	s.Clients = append(s.Clients, c)
+	return nil
+}
+
+func (s *Status) addRoute(r Route) error {
+	s.Routes = append(s.Routes, r)
+	return nil
+}
+
+func (s *Status) setStats(st Stats) error {
+	s.MaxQueue = st.MaxQueue
+	return nil
+}
+
 // parseFn is the type returned from parseLine and is used to further
 // process the line
-type parseFn func(*Status, string) error
+type parseFn func(sink, string) error
 
 // EOF is returned when the end of the file is reached in parseLine
 var EOF = errors.New("EOF reached")
@@ -135,76 +181,84 @@ var addrType = reflect.TypeOf(Addr{})
 var netIPType = reflect.TypeOf(net.IP{})
 var routeAddrType = reflect.TypeOf(RouteAddr{})
 
-// parseStructParts takes a struct and fills in the fields based on reflection
-// and the order of the []string slice passed in
-func parseStructParts(v reflect.Value, parts []string) error {
-	var f reflect.Value
-	for i := 0; i < v.NumField() && i < len(parts); i += 1 {
-		f = v.Field(i)
-		if !f.CanSet() {
-			continue
+// setField sets a single struct field from its string representation,
+// dispatching on the field's kind/type the same way parseStructParts does
+// for a whole struct.
+func setField(f reflect.Value, part string) error {
+	if !f.CanSet() {
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Uint64:
+		p, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.SetUint(p)
+	case reflect.Int64:
+		p, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return err
 		}
-		switch f.Kind() {
-		case reflect.Uint64:
-			p, err := strconv.ParseUint(parts[i], 10, 64)
+		f.SetInt(p)
+	case reflect.String:
+		f.SetString(part)
+	default:
+		t := f.Type()
+		switch {
+		case t.AssignableTo(timeType):
+			p, err := parseTime(part)
 			if err != nil {
 				return err
 			}
-			f.SetUint(p)
-		case reflect.Int64:
-			p, err := strconv.ParseInt(parts[i], 10, 64)
+			f.Set(reflect.ValueOf(p))
+		case t.AssignableTo(addrType):
+			p, err := parseAddr(part)
 			if err != nil {
 				return err
 			}
-			f.SetInt(p)
-		case reflect.String:
-			f.SetString(parts[i])
-		default:
-			t := f.Type()
-			switch {
-			case t.AssignableTo(timeType):
-				p, err := parseTime(parts[i])
-				if err != nil {
-					return err
-				}
-				f.Set(reflect.ValueOf(p))
-			case t.AssignableTo(addrType):
-				p, err := parseAddr(parts[i])
-				if err != nil {
-					return err
-				}
-				f.Set(reflect.ValueOf(p))
-			case t.AssignableTo(netIPType):
-				p := net.ParseIP(parts[i])
-				if p == nil {
-					return errors.New("Invalid IP encountered")
-				}
-				f.Set(reflect.ValueOf(p))
-			case t.AssignableTo(routeAddrType):
-				p, err := parseRouteAddr(parts[i])
-				if err != nil {
-					return err
-				}
-				f.Set(reflect.ValueOf(p))
-			default:
-				return errors.New("Unknown type encountered in struct")
+			f.Set(reflect.ValueOf(p))
+		case t.AssignableTo(netIPType):
+			p := net.ParseIP(part)
+			if p == nil {
+				return errors.New("Invalid IP encountered")
 			}
+			f.Set(reflect.ValueOf(p))
+		case t.AssignableTo(routeAddrType):
+			p, err := parseRouteAddr(part)
+			if err != nil {
+				return err
+			}
+			f.Set(reflect.ValueOf(p))
+		default:
+			return errors.New("Unknown type encountered in struct")
+		}
+	}
+	return nil
+}
+
+// parseStructParts takes a struct and fills in the fields based on reflection
+// and the order of the []string slice passed in
+func parseStructParts(v reflect.Value, parts []string) error {
+	for i := 0; i < v.NumField() && i < len(parts); i += 1 {
+		if err := setField(v.Field(i), parts[i]); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // parseNothing is a placeholder function that does nothing
-func parseNothing(s *Status, text string) error {
+func parseNothing(sk sink, text string) error {
 	return nil
 }
 
 // parseUpdated parses the "Updated," line
-func parseUpdated(s *Status, text string) error {
+func parseUpdated(sk sink, text string) error {
 	if text[0:7] == "Updated" {
 		t, err := parseTime(text[8:])
 		if err == nil {
-			s.Updated = t
+			err = sk.setUpdated(t)
 		}
 		return err
 	}
@@ -212,7 +266,7 @@ func parseUpdated(s *Status, text string) error {
 }
 
 // parseClient parses lines in the CLIENT LIST section
-func parseClient(s *Status, text string) error {
+func parseClient(sk sink, text string) error {
 	parts := strings.Split(text, ",")
 	c := Client{}
 	v := reflect.ValueOf(&c).Elem()
@@ -225,12 +279,11 @@ func parseClient(s *Status, text string) error {
 		}
 		return err
 	}
-	s.Clients = append(s.Clients, c)
-	return nil
+	return sk.addClient(c)
 }
 
 // parseRoute parses lines in the ROUTING TABLE section
-func parseRoute(s *Status, text string) error {
+func parseRoute(sk sink, text string) error {
 	parts := strings.Split(text, ",")
 	c := Route{}
 	v := reflect.ValueOf(&c).Elem()
@@ -243,46 +296,46 @@ func parseRoute(s *Status, text string) error {
 		}
 		return err
 	}
-	s.Routes = append(s.Routes, c)
-	return nil
+	return sk.addRoute(c)
 }
 
 // parseStat parses lines in the GLOBAL STATS section
-func parseStat(s *Status, text string) error {
+func parseStat(sk sink, text string) error {
 	parts := strings.Split(text, ",")
 	if strings.Contains(parts[0], "queue length") {
 		p, err := strconv.ParseUint(parts[1], 10, 64)
 		if err != nil {
 			return err
 		}
-		s.MaxQueue = p
+		return sk.setStats(Stats{MaxQueue: p})
 	}
 	return nil
 }
 
-// parseLine accepts a string and returns the appropriate parse* function
-// for detailed, specific parsing of the line
-func (s *Status) parseLine(text string) (parseFn, error) {
+// nextParseFn accepts a line of text and the current section state, and
+// returns the appropriate parse* function for detailed, specific parsing of
+// the line, advancing state if the line is itself a section header
+func nextParseFn(state *readState, text string) (parseFn, error) {
 	var err error
 	// for all these section headers we want to return parseNothing since we
 	// just want to set the state from the header not actually process anything
 	fn := parseNothing
 	if text == "END" || text == "" {
-		s.state = stateEnd
+		*state = stateEnd
 		err = EOF
 	} else if strings.Contains(text, "CLIENT LIST") {
-		s.state = stateClients
+		*state = stateClients
 	} else if strings.Contains(text, "ROUTING TABLE") {
-		s.state = stateRoutes
+		*state = stateRoutes
 	} else if strings.Contains(text, "GLOBAL STATS") {
-		s.state = stateStats
+		*state = stateStats
 	} else if strings.HasPrefix(text, "Updated,") {
 		// since updated is in the middle of a section do not change the state
 		fn = parseUpdated
 	} else {
 		// return the appropriate fn for the state we were in as determined by
 		// the last header
-		switch (s.state) {
+		switch *state {
 		case stateClients:
 			fn = parseClient
 		case stateRoutes:
@@ -300,10 +353,21 @@ func (s *Status) parseLine(text string) (parseFn, error) {
 	return fn, err
 }
 
-// Parses an io.Reader into a Status
+// Parses an io.Reader into a Status. The format (version 1, 2, or 3) is
+// auto-detected from the first line.
 func Parse(r io.Reader) (*Status, error) {
 	s := &Status{}
+	if err := ParseStream(r, statusBuilder{s}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseV1Stream parses the version 1, human-readable status log format,
+// feeding each record it encounters into sk
+func parseV1Stream(r io.Reader, sk sink) error {
 	scanner := bufio.NewScanner(r)
+	var state readState
 	var t string
 	var err error
 	var fn parseFn
@@ -311,23 +375,19 @@ func Parse(r io.Reader) (*Status, error) {
 	for scanner.Scan() {
 		line++
 		t = scanner.Text()
-		fn, err = s.parseLine(t)
+		fn, err = nextParseFn(&state, t)
 		if err == nil {
-			// parse the line using the returned function from parseLine
-			err = fn(s, t)
+			// parse the line using the returned function from nextParseFn
+			err = fn(sk, t)
 		}
 		if err != nil {
-			if err == EOF {
-				break
+			if err == EOF || err == ErrStop {
+				return nil
 			}
-			return nil, errors.New(fmt.Sprintf("Error on line %d: %s", line, err))
+			return errors.New(fmt.Sprintf("Error on line %d: %s", line, err))
 		}
 	}
-	err = scanner.Err()
-	if err != nil {
-		return nil, err
-	}
-	return s, nil
+	return scanner.Err()
 }
 
 // Helper that reads a file into Parse
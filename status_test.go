@@ -1,7 +1,6 @@
 package openvpn
 
 import (
-	"github.com/levenlabs/go-openvpn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net"
@@ -27,12 +26,12 @@ END`
 	name := "test1"
 	remote := Addr{net.ParseIP("6.6.6.6"), 1000}
 	routes := make([]RouteAddr, 3)
-	routes[0] = RouteAddr{net.IPNet{net.ParseIP("10.0.0.1"), net.CIDRMask(32, 32)}, false}
-	routes[1] = RouteAddr{net.IPNet{net.ParseIP("10.1.0.1"), net.CIDRMask(32, 32)}, true}
-	routes[2] = RouteAddr{net.IPNet{net.ParseIP("10.3.0.1"), net.CIDRMask(16, 32)}, false}
+	routes[0] = RouteAddr{IPNet: net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(32, 32)}, Remote: false}
+	routes[1] = RouteAddr{IPNet: net.IPNet{IP: net.ParseIP("10.1.0.1"), Mask: net.CIDRMask(32, 32)}, Remote: true}
+	routes[2] = RouteAddr{IPNet: net.IPNet{IP: net.ParseIP("10.3.0.1"), Mask: net.CIDRMask(16, 32)}, Remote: false}
 	t1 := time.Unix(1446737683, 0) // Thu Nov  5 15:34:43 2015
 
-	s, err := openvpn.Parse(strings.NewReader(f))
+	s, err := Parse(strings.NewReader(f))
 	require.Nil(t, err)
 	assert.True(t, s.Updated.Equal(t1))
 
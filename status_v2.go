@@ -0,0 +1,171 @@
+package openvpn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// detectStatusVersion inspects the first line of a status log and returns
+// which --status-version produced it: 2 for CSV, 3 for TSV, and 1 for
+// anything else, including the human-readable format.
+func detectStatusVersion(firstLine string) int {
+	switch {
+	case strings.HasPrefix(firstLine, "TITLE\t"):
+		return 3
+	case strings.HasPrefix(firstLine, "TITLE,"):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// clientV2Order maps each column of a version 2/3 CLIENT_LIST record to the
+// index of the Client field it fills in. A value of -1 means the column is
+// ignored (e.g. it duplicates information already carried by another
+// column in a different format).
+//
+// CLIENT_LIST,Common Name,Real Address,Virtual Address,Virtual IPv6 Address,
+//
+//	Bytes Received,Bytes Sent,Connected Since,Connected Since (time_t),
+//	Username,Client ID,Peer ID,Data Channel Cipher
+var clientV2Order = []int{0, 1, 5, 6, 2, 3, 4, -1, 7, 8, 9, 10}
+
+// routeV2Order maps each column of a version 2/3 ROUTING_TABLE record to the
+// index of the Route field it fills in.
+//
+// ROUTING_TABLE,Virtual Address,Common Name,Real Address,Last Ref,
+//
+//	Last Ref (time_t)
+var routeV2Order = []int{0, 1, 2, 3, -1}
+
+// parseStructPartsOrdered is like parseStructParts but allows the columns to
+// appear in a different order than the struct's fields, and to skip columns
+// entirely, as described by order.
+func parseStructPartsOrdered(v reflect.Value, parts []string, order []int) error {
+	for i := 0; i < len(order) && i < len(parts); i += 1 {
+		fi := order[i]
+		if fi < 0 || parts[i] == "" {
+			// -1 means the column is intentionally ignored, and an empty
+			// column means the field wasn't reported for this client/route
+			continue
+		}
+		if err := setField(v.Field(fi), parts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseClientV2 parses the fields of a CLIENT_LIST record, given with the
+// leading "CLIENT_LIST" field already stripped
+func parseClientV2(sk sink, parts []string) error {
+	c := Client{}
+	v := reflect.ValueOf(&c).Elem()
+	if err := parseStructPartsOrdered(v, parts, clientV2Order); err != nil {
+		return err
+	}
+	return sk.addClient(c)
+}
+
+// parseRouteV2 parses the fields of a ROUTING_TABLE record, given with the
+// leading "ROUTING_TABLE" field already stripped
+func parseRouteV2(sk sink, parts []string) error {
+	c := Route{}
+	v := reflect.ValueOf(&c).Elem()
+	if err := parseStructPartsOrdered(v, parts, routeV2Order); err != nil {
+		return err
+	}
+	return sk.addRoute(c)
+}
+
+// parseStatV2 parses the fields of a GLOBAL_STATS record, given with the
+// leading "GLOBAL_STATS" field already stripped
+func parseStatV2(sk sink, parts []string) error {
+	if len(parts) < 2 {
+		return nil
+	}
+	if strings.Contains(parts[0], "queue length") {
+		p, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		return sk.setStats(Stats{MaxQueue: p})
+	}
+	return nil
+}
+
+// titleSetter is an optional extension of sink that also accepts the
+// TITLE/TIME records found in version 2/3 logs. *Status implements it; the
+// sink ParseStream builds around a Handler only forwards Title/Time if the
+// Handler also implements TitleHandler.
+type titleSetter interface {
+	setTitle(string) error
+	setTime(time.Time) error
+}
+
+func (s *Status) setTitle(title string) error {
+	s.Title = title
+	return nil
+}
+
+func (s *Status) setTime(t time.Time) error {
+	s.Time = t
+	return nil
+}
+
+// parseCSVStream parses the version 2 (CSV) or version 3 (TSV) status log
+// format, dispatching on the record type found at the start of each line and
+// feeding each record it encounters into sk
+func parseCSVStream(r io.Reader, sep string, sk sink) error {
+	ts, _ := sk.(titleSetter)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		t := scanner.Text()
+		if t == "" {
+			continue
+		}
+		parts := strings.Split(t, sep)
+		var err error
+		switch parts[0] {
+		case "TITLE":
+			if len(parts) > 1 && ts != nil {
+				err = ts.setTitle(parts[1])
+			}
+		case "TIME":
+			if len(parts) > 1 {
+				var when time.Time
+				if when, err = parseTime(parts[1]); err == nil && ts != nil {
+					err = ts.setTime(when)
+				}
+			}
+		case "HEADER":
+			// describes the column layout of the record that follows, but
+			// the layout is fixed per status version so there's nothing to
+			// do with it
+		case "CLIENT_LIST":
+			err = parseClientV2(sk, parts[1:])
+		case "ROUTING_TABLE":
+			err = parseRouteV2(sk, parts[1:])
+		case "GLOBAL_STATS":
+			err = parseStatV2(sk, parts[1:])
+		case "END":
+			return nil
+		default:
+			// unknown record type; ignore so future additions don't break us
+		}
+		if err != nil {
+			if err == ErrStop {
+				return nil
+			}
+			return fmt.Errorf("Error on line %d: %s", line, err)
+		}
+	}
+	return scanner.Err()
+}
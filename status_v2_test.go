@@ -0,0 +1,103 @@
+package openvpn_test
+
+import (
+	"net"
+	"strings"
+	. "testing"
+	"time"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+// fixtures for the three status log formats, all describing the same
+// single client/route/stats snapshot
+const v1Fixture = `OpenVPN CLIENT LIST
+Updated,Thu Nov  5 15:34:43 2015
+Common Name,Real Address,Bytes Received,Bytes Sent,Connected Since
+test1,6.6.6.6:1000,100,98,Thu Nov  5 15:34:43 2015
+ROUTING TABLE
+Virtual Address,Common Name,Real Address,Last Ref
+10.0.0.1,test1,6.6.6.6:1000,Thu Nov  5 15:34:43 2015
+GLOBAL STATS
+Max bcast / mcast queue length,39
+END`
+
+const v2Fixture = `TITLE,OpenVPN 2.4.7 x86_64-pc-linux-gnu
+TIME,Thu Nov  5 15:34:43 2015,1446737683
+HEADER,CLIENT_LIST,Common Name,Real Address,Virtual Address,Virtual IPv6 Address,Bytes Received,Bytes Sent,Connected Since,Connected Since (time_t),Username,Client ID,Peer ID,Data Channel Cipher
+CLIENT_LIST,test1,6.6.6.6:1000,10.8.0.2,,100,98,Thu Nov  5 15:34:43 2015,1446737683,UNDEF,0,1,AES-256-GCM
+HEADER,ROUTING_TABLE,Virtual Address,Common Name,Real Address,Last Ref,Last Ref (time_t)
+ROUTING_TABLE,10.0.0.1,test1,6.6.6.6:1000,Thu Nov  5 15:34:43 2015,1446737683
+GLOBAL_STATS,Max bcast/mcast queue length,39
+END`
+
+var v3Fixture = strings.Replace(v2Fixture, ",", "\t", -1)
+
+func TestParseVersions(t *T) {
+	t1 := time.Unix(1446737683, 0) // Thu Nov  5 15:34:43 2015
+
+	cases := []struct {
+		name    string
+		fixture string
+	}{
+		{"v1", v1Fixture},
+		{"v2", v2Fixture},
+		{"v3", v3Fixture},
+	}
+
+	for _, c := range cases {
+		s, err := openvpn.Parse(strings.NewReader(c.fixture))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		if len(s.Clients) != 1 {
+			t.Fatalf("%s: expected 1 client, got %d", c.name, len(s.Clients))
+		}
+		cl := s.Clients[0]
+		if cl.CommonName != "test1" {
+			t.Errorf("%s: expected CommonName test1, got %q", c.name, cl.CommonName)
+		}
+		if !cl.RealAddress.IP.Equal(net.ParseIP("6.6.6.6")) || cl.RealAddress.Port != 1000 {
+			t.Errorf("%s: unexpected RealAddress: %+v", c.name, cl.RealAddress)
+		}
+		if cl.BytesReceived != 100 || cl.BytesSent != 98 {
+			t.Errorf("%s: unexpected byte counts: %+v", c.name, cl)
+		}
+		if !cl.Since.Equal(t1) {
+			t.Errorf("%s: unexpected Since: %s", c.name, cl.Since)
+		}
+
+		if len(s.Routes) != 1 {
+			t.Fatalf("%s: expected 1 route, got %d", c.name, len(s.Routes))
+		}
+		if s.Routes[0].CommonName != "test1" {
+			t.Errorf("%s: unexpected route CommonName: %q", c.name, s.Routes[0].CommonName)
+		}
+
+		if s.MaxQueue != 39 {
+			t.Errorf("%s: expected MaxQueue 39, got %d", c.name, s.MaxQueue)
+		}
+
+		if c.name != "v1" {
+			if cl.VirtualAddress == nil || !cl.VirtualAddress.Equal(net.ParseIP("10.8.0.2")) {
+				t.Errorf("%s: unexpected VirtualAddress: %v", c.name, cl.VirtualAddress)
+			}
+			if cl.Username != "UNDEF" {
+				t.Errorf("%s: unexpected Username: %q", c.name, cl.Username)
+			}
+			if cl.PeerID != 1 {
+				t.Errorf("%s: unexpected PeerID: %d", c.name, cl.PeerID)
+			}
+			if cl.DataChannelCipher != "AES-256-GCM" {
+				t.Errorf("%s: unexpected DataChannelCipher: %q", c.name, cl.DataChannelCipher)
+			}
+			if !s.Time.Equal(t1) {
+				t.Errorf("%s: unexpected Time: %s", c.name, s.Time)
+			}
+			if !strings.HasPrefix(s.Title, "OpenVPN") {
+				t.Errorf("%s: unexpected Title: %q", c.name, s.Title)
+			}
+		}
+	}
+}
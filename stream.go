@@ -0,0 +1,102 @@
+package openvpn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Stats holds the contents of the GLOBAL STATS/GLOBAL_STATS section of a
+// status log.
+type Stats struct {
+	MaxQueue uint64
+}
+
+// ErrStop can be returned by any Handler method to cleanly abort parsing
+// part way through. ParseStream stops reading and returns nil, the same as
+// if it had reached the end of the log.
+var ErrStop = errors.New("openvpn: stop requested by handler")
+
+// Handler receives records as ParseStream reads them, instead of having
+// them accumulate in memory the way Parse does. Any method may return
+// ErrStop to abort parsing early, or any other error to abort with that
+// error.
+type Handler interface {
+	OnUpdated(time.Time) error
+	OnClient(Client) error
+	OnRoute(Route) error
+	OnStats(Stats) error
+}
+
+// TitleHandler is an optional extension of Handler for callers who also
+// want the Title/Time records found in version 2/3 logs. Version 1 logs
+// never call these methods.
+type TitleHandler interface {
+	Handler
+	OnTitle(string) error
+	OnTime(time.Time) error
+}
+
+// handlerSink adapts a Handler to the internal sink interface used by the
+// line parsers, optionally forwarding Title/Time to a TitleHandler.
+type handlerSink struct {
+	h Handler
+}
+
+func (hs handlerSink) setUpdated(t time.Time) error { return hs.h.OnUpdated(t) }
+func (hs handlerSink) addClient(c Client) error     { return hs.h.OnClient(c) }
+func (hs handlerSink) addRoute(r Route) error       { return hs.h.OnRoute(r) }
+func (hs handlerSink) setStats(s Stats) error       { return hs.h.OnStats(s) }
+
+func (hs handlerSink) setTitle(title string) error {
+	if th, ok := hs.h.(TitleHandler); ok {
+		return th.OnTitle(title)
+	}
+	return nil
+}
+
+func (hs handlerSink) setTime(t time.Time) error {
+	if th, ok := hs.h.(TitleHandler); ok {
+		return th.OnTime(t)
+	}
+	return nil
+}
+
+// statusBuilder is the Handler Parse uses internally to accumulate a
+// Status out of a stream of records.
+type statusBuilder struct {
+	s *Status
+}
+
+func (b statusBuilder) OnUpdated(t time.Time) error { return b.s.setUpdated(t) }
+func (b statusBuilder) OnClient(c Client) error     { return b.s.addClient(c) }
+func (b statusBuilder) OnRoute(r Route) error       { return b.s.addRoute(r) }
+func (b statusBuilder) OnStats(st Stats) error      { return b.s.setStats(st) }
+func (b statusBuilder) OnTitle(title string) error  { return b.s.setTitle(title) }
+func (b statusBuilder) OnTime(t time.Time) error    { return b.s.setTime(t) }
+
+// ParseStream parses an io.Reader the same way Parse does, but instead of
+// accumulating every Client/Route into memory it calls h as each record is
+// encountered. This is the better fit for very large status files where
+// callers only want to observe, not store, the whole thing.
+func ParseStream(r io.Reader, h Handler) error {
+	br := bufio.NewReader(r)
+	first, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	// stitch the already-consumed first line back onto the reader so the
+	// version-specific parser below sees the whole stream
+	full := io.MultiReader(strings.NewReader(first), br)
+	sk := handlerSink{h}
+	switch detectStatusVersion(strings.TrimRight(first, "\r\n")) {
+	case 2:
+		return parseCSVStream(full, ",", sk)
+	case 3:
+		return parseCSVStream(full, "\t", sk)
+	default:
+		return parseV1Stream(full, sk)
+	}
+}
@@ -0,0 +1,76 @@
+package openvpn_test
+
+import (
+	"strings"
+	. "testing"
+	"time"
+
+	openvpn "github.com/levenlabs/go-openvpn"
+)
+
+type recordingHandler struct {
+	clients []openvpn.Client
+	routes  []openvpn.Route
+	stats   []openvpn.Stats
+	stopAt  int
+}
+
+func (h *recordingHandler) OnUpdated(time.Time) error { return nil }
+
+func (h *recordingHandler) OnClient(c openvpn.Client) error {
+	h.clients = append(h.clients, c)
+	if h.stopAt > 0 && len(h.clients) == h.stopAt {
+		return openvpn.ErrStop
+	}
+	return nil
+}
+
+func (h *recordingHandler) OnRoute(r openvpn.Route) error {
+	h.routes = append(h.routes, r)
+	return nil
+}
+
+func (h *recordingHandler) OnStats(s openvpn.Stats) error {
+	h.stats = append(h.stats, s)
+	return nil
+}
+
+func TestParseStreamStop(t *T) {
+	h := &recordingHandler{stopAt: 1}
+	err := openvpn.ParseStream(strings.NewReader(v1Fixture), h)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(h.clients) != 1 {
+		t.Fatalf("expected ErrStop to abort after 1 client, got %d", len(h.clients))
+	}
+	// the rest of the log, including the route and stats sections, should
+	// never have been reached
+	if len(h.routes) != 0 || len(h.stats) != 0 {
+		t.Fatalf("expected no routes/stats after stopping early, got %+v / %+v", h.routes, h.stats)
+	}
+}
+
+func TestDecoder(t *T) {
+	d := openvpn.NewDecoder(strings.NewReader(v1Fixture))
+	defer d.Close()
+
+	var clients, routes, stats int
+	for {
+		rec, err := d.Next()
+		if err != nil {
+			break
+		}
+		switch rec.Type {
+		case openvpn.RecordClient:
+			clients++
+		case openvpn.RecordRoute:
+			routes++
+		case openvpn.RecordStats:
+			stats++
+		}
+	}
+	if clients != 1 || routes != 1 || stats != 1 {
+		t.Fatalf("expected 1 of each record, got clients=%d routes=%d stats=%d", clients, routes, stats)
+	}
+}